@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Route describes a single named translator destination and the files that
+// its secrets are read from. Secrets are never taken from the config file
+// itself, nor from the incoming webhook request, so that they can be
+// rotated on disk (eg. by Vault or a Kubernetes secret mount) without
+// redeploying the translator and never end up in a reverse proxy's or the
+// translator's own request logs. Each vendor backend only reads the fields
+// relevant to its API.
+type Route struct {
+	APIKeyFile      string `yaml:"api_key_file"`      // VictorOps, OpsGenie
+	RoutingKeyFile  string `yaml:"routing_key_file"`  // VictorOps, PagerDuty
+	AccessTokenFile string `yaml:"access_token_file"` // DingTalk, Feishu
+	SecretFile      string `yaml:"secret_file"`       // Feishu (optional, enables request signing)
+	KeyFile         string `yaml:"key_file"`          // WeCom
+}
+
+// Config is the on-disk translator configuration. Routes are looked up by
+// name via the `route` query parameter on incoming webhook requests.
+type Config struct {
+	Routes map[string]Route `yaml:"routes"`
+}
+
+// Load reads and parses the YAML configuration file at path.
+func Load(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %s", err.Error())
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("could not parse config file: %s", err.Error())
+	}
+
+	return &c, nil
+}
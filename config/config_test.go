@@ -0,0 +1,76 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yml")
+	contents := `
+routes:
+  team-a:
+    api_key_file: /secrets/team-a/api_key
+    routing_key_file: /secrets/team-a/routing_key
+  team-b:
+    access_token_file: /secrets/team-b/access_token
+`
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("could not write config file: %s", err.Error())
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(cfg.Routes) != 2 {
+		t.Fatalf("len(cfg.Routes) = %d, want 2", len(cfg.Routes))
+	}
+
+	teamA, ok := cfg.Routes["team-a"]
+	if !ok {
+		t.Fatal("missing route 'team-a'")
+	}
+	if teamA.APIKeyFile != "/secrets/team-a/api_key" {
+		t.Errorf("teamA.APIKeyFile = %q, want %q", teamA.APIKeyFile, "/secrets/team-a/api_key")
+	}
+	if teamA.RoutingKeyFile != "/secrets/team-a/routing_key" {
+		t.Errorf("teamA.RoutingKeyFile = %q, want %q", teamA.RoutingKeyFile, "/secrets/team-a/routing_key")
+	}
+
+	if _, ok := cfg.Routes["does-not-exist"]; ok {
+		t.Error("lookup of an unknown route unexpectedly succeeded")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(os.TempDir(), "does-not-exist-config-test.yml")); err == nil {
+		t.Error("Load() of a missing file, want error")
+	}
+}
+
+func TestLoadInvalidYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(path, []byte("routes: [this is not a map]"), 0600); err != nil {
+		t.Fatalf("could not write config file: %s", err.Error())
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of invalid YAML, want error")
+	}
+}
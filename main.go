@@ -1,15 +1,65 @@
 package main
 
 import (
+	"flag"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/alexrudd/am-rest-translator/config"
 	"github.com/alexrudd/am-rest-translator/translators"
+	"github.com/alexrudd/am-rest-translator/translators/tmpl"
 )
 
 func main() {
+	configPath := flag.String("config", "/etc/am-rest-translator/config.yml", "path to the routes configuration file")
+	templatesDir := flag.String("templates-dir", "", "directory of template overrides for outbound vendor messages")
+	notifyConcurrency := flag.Int("notify-concurrency", translators.DefaultConcurrency, "maximum number of vendor notifications to have in flight at once")
+	notifyTimeout := flag.Duration("notify-timeout", translators.DefaultTimeout, "per-attempt timeout for a single vendor notification")
+	notifyMaxAttempts := flag.Int("notify-max-attempts", translators.DefaultMaxAttempts, "maximum attempts for a vendor notification that fails transiently")
+	telemetryAddr := flag.String("telemetry-addr", "", "address to serve /metrics on; served on the main listener if empty")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Could not load config: %s", err.Error())
+	}
+	templates, err := tmpl.New(*templatesDir)
+	if err != nil {
+		log.Fatalf("Could not load templates: %s", err.Error())
+	}
+	translators.Init(cfg, templates)
+	translators.Configure(*notifyConcurrency, *notifyTimeout, *notifyMaxAttempts)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			if err := templates.Reload(); err != nil {
+				log.Errorf("Could not reload templates: %s", err.Error())
+				continue
+			}
+			log.Infof("Reloaded templates from %s", *templatesDir)
+		}
+	}()
+
 	for path, handle := range translators.Handles {
 		http.HandleFunc(path, handle)
 	}
+
+	if *telemetryAddr == "" {
+		http.Handle("/metrics", promhttp.Handler())
+	} else {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			log.Fatal(http.ListenAndServe(*telemetryAddr, mux))
+		}()
+	}
+
 	log.Fatal(http.ListenAndServe(":80", nil))
 }
@@ -0,0 +1,57 @@
+// Package metrics holds the Prometheus collectors that make the
+// translator itself observable: how many requests each translator
+// handled, how its calls to vendor upstreams performed, and how many
+// individual alerts it forwarded.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// RequestsTotal counts translation requests handled, by translator and
+	// outcome ("ok", "decode_error", "bad_request", "internal_error",
+	// "upstream_error", "partial_failure").
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "amrt_requests_total",
+		Help: "Total number of translation requests handled, by translator and outcome.",
+	}, []string{"translator", "status"})
+
+	// UpstreamRequestsTotal counts requests sent to vendor upstreams, by
+	// translator and response code (or "error" for a failed attempt).
+	UpstreamRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "amrt_upstream_requests_total",
+		Help: "Total number of requests sent to vendor upstreams, by translator and response code.",
+	}, []string{"translator", "code"})
+
+	// UpstreamDuration observes the latency of requests sent to vendor
+	// upstreams, by translator.
+	UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "amrt_upstream_duration_seconds",
+		Help:    "Latency of requests sent to vendor upstreams, by translator.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"translator"})
+
+	// DecodeErrorsTotal counts Alertmanager webhook payloads that failed to
+	// decode or were missing required fields.
+	DecodeErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "amrt_decode_errors_total",
+		Help: "Total number of Alertmanager webhook payloads that failed to decode.",
+	})
+
+	// AlertsForwardedTotal counts individual alerts forwarded to a vendor,
+	// by translator and the Alertmanager group status ("firing"/"resolved")
+	// that produced them.
+	AlertsForwardedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "amrt_alerts_forwarded_total",
+		Help: "Total number of individual alerts forwarded to a vendor, by translator and message type.",
+	}, []string{"translator", "message_type"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		RequestsTotal,
+		UpstreamRequestsTotal,
+		UpstreamDuration,
+		DecodeErrorsTotal,
+		AlertsForwardedTotal,
+	)
+}
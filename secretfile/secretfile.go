@@ -0,0 +1,54 @@
+// Package secretfile provides a small cache for secrets that are read from
+// disk, so that operators can rotate credentials (eg. a Vault-templated
+// file) without restarting the translator.
+package secretfile
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File is a secret value backed by a file on disk. It is cached in memory
+// and only re-read when the file's modification time changes.
+type File struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	value   string
+}
+
+// New returns a File that lazily loads path on the first call to Get.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// Get returns the current contents of the file, with surrounding whitespace
+// trimmed. The file is only re-read from disk when its modification time
+// has changed since the last read.
+func (f *File) Get() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	if f.loaded && info.ModTime().Equal(f.modTime) {
+		return f.value, nil
+	}
+
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.value = strings.TrimSpace(string(b))
+	f.modTime = info.ModTime()
+	f.loaded = true
+	return f.value, nil
+}
@@ -0,0 +1,99 @@
+package secretfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretfile")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secret")
+	write := func(contents string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("could not write secret file: %s", err.Error())
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("could not set mod time: %s", err.Error())
+		}
+	}
+
+	t0 := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write("  super-secret  \n", t0)
+
+	f := New(path)
+
+	got, err := f.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "super-secret" {
+		t.Errorf("Get() = %q, want %q", got, "super-secret")
+	}
+
+	// Change the file on disk without bumping mtime: the cached value
+	// should still be returned.
+	if err := ioutil.WriteFile(path, []byte("changed"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %s", err.Error())
+	}
+	if err := os.Chtimes(path, t0, t0); err != nil {
+		t.Fatalf("could not set mod time: %s", err.Error())
+	}
+	got, err = f.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "super-secret" {
+		t.Errorf("Get() after same-mtime change = %q, want cached %q", got, "super-secret")
+	}
+
+	// Bumping the mtime should force a re-read.
+	write("changed", t0.Add(time.Second))
+	got, err = f.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "changed" {
+		t.Errorf("Get() after mtime bump = %q, want %q", got, "changed")
+	}
+
+	// A legitimately empty (or whitespace-only) secret file must not force
+	// a re-read on every subsequent call: change the contents without
+	// bumping mtime and confirm the cached empty value still wins.
+	emptyModTime := t0.Add(2 * time.Second)
+	write("   \n", emptyModTime)
+	got, err = f.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "" {
+		t.Errorf("Get() = %q, want empty string", got)
+	}
+	if err := ioutil.WriteFile(path, []byte("should-not-be-seen"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %s", err.Error())
+	}
+	if err := os.Chtimes(path, emptyModTime, emptyModTime); err != nil {
+		t.Fatalf("could not set mod time: %s", err.Error())
+	}
+	got, err = f.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "" {
+		t.Errorf("Get() with unchanged mtime after caching an empty value = %q, want cached empty string", got)
+	}
+}
+
+func TestFileGetMissing(t *testing.T) {
+	f := New(filepath.Join(os.TempDir(), "does-not-exist-secretfile-test"))
+	if _, err := f.Get(); err == nil {
+		t.Error("Get() on a missing file, want error")
+	}
+}
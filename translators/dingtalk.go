@@ -0,0 +1,113 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func init() {
+	register(dingtalkTranslator{})
+}
+
+const dingtalkRouteParam = "route"
+
+// dingtalkRoute holds the cached secret file for a single named DingTalk
+// route, as configured by the operator.
+type dingtalkRoute struct {
+	accessToken *secretfile.File
+}
+
+var dingtalkRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]dingtalkRoute
+}{routes: make(map[string]dingtalkRoute)}
+
+// configureDingtalkRoutes registers the DingTalk routes from cfg, replacing
+// any previously registered routes. Called from Init.
+func configureDingtalkRoutes(cfg *config.Config) {
+	routes := make(map[string]dingtalkRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		routes[name] = dingtalkRoute{accessToken: secretfile.New(r.AccessTokenFile)}
+	}
+
+	dingtalkRoutes.mu.Lock()
+	dingtalkRoutes.routes = routes
+	dingtalkRoutes.mu.Unlock()
+}
+
+// dingtalkMarkdown - the "markdown" message type for DingTalk's custom
+// robot webhook.
+// https://open.dingtalk.com/document/robots/custom-robot-access
+type dingtalkMarkdown struct {
+	MsgType  string `json:"msgtype"`
+	Markdown struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	} `json:"markdown"`
+}
+
+// dingtalkTranslator implements Translator for DingTalk's custom robot
+// webhook.
+type dingtalkTranslator struct{}
+
+func (dingtalkTranslator) Path() string { return "/dingtalk" }
+
+func (dingtalkTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(dingtalkRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", dingtalkRouteParam)
+	}
+
+	dingtalkRoutes.mu.RLock()
+	route, ok := dingtalkRoutes.routes[routeName]
+	dingtalkRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	token, err := route.accessToken.Get()
+	if err != nil {
+		return "", fmt.Errorf("could not read access_token_file for route %s: %s", routeName, err.Error())
+	}
+
+	return "https://oapi.dingtalk.com/robot/send?access_token=" + url.QueryEscape(token), nil
+}
+
+// Translate renders the alert group as a single markdown message, titled
+// with its status, with one bullet per alert when firing and a single
+// recovery bullet when resolved.
+func (dingtalkTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+
+	var title string
+	var lines []string
+	switch wm.Status {
+	case "firing":
+		title = fmt.Sprintf("🔥 %s firing", displayName)
+		for _, alert := range wm.Alerts {
+			lines = append(lines, fmt.Sprintf("- **%s**: %s", alert.Labels["alertname"], alert.Annotations["summary"]))
+		}
+	case "resolved":
+		title = fmt.Sprintf("✅ %s resolved", displayName)
+		lines = append(lines, "- all alerts in this group have recovered")
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
+	}
+
+	msg := dingtalkMarkdown{MsgType: "markdown"}
+	msg.Markdown.Title = title
+	msg.Markdown.Text = "#### " + title + "\n" + strings.Join(lines, "\n")
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dingtalk message: %s", err.Error())
+	}
+	return []OutboundRequest{{ContentType: "application/json", Body: b}}, nil
+}
@@ -0,0 +1,74 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func TestDingtalkEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureDingtalkRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {AccessTokenFile: writeSecret(t, dir, "access_token", "tok123")},
+	}})
+
+	tr := dingtalkTranslator{}
+
+	if _, err := tr.Endpoint(url.Values{dingtalkRouteParam: {"does-not-exist"}}); err == nil {
+		t.Error("Endpoint() with an unknown route, want error")
+	}
+
+	endpoint, err := tr.Endpoint(url.Values{dingtalkRouteParam: {"team-a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := "https://oapi.dingtalk.com/robot/send?access_token=tok123"
+	if endpoint != want {
+		t.Errorf("Endpoint() = %q, want %q", endpoint, want)
+	}
+}
+
+func TestDingtalkTranslate(t *testing.T) {
+	tr := dingtalkTranslator{}
+
+	t.Run("firing", func(t *testing.T) {
+		wm := webhook.Message{
+			Status:      "firing",
+			GroupLabels: webhook.KV{"alertname": "HighLatency"},
+			Alerts: []webhook.Alert{
+				{Labels: webhook.KV{"alertname": "HighLatency"}, Annotations: webhook.KV{"summary": "latency is high"}},
+			},
+		}
+		reqs, err := tr.Translate(wm, url.Values{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var msg dingtalkMarkdown
+		if err := json.Unmarshal(reqs[0].Body, &msg); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if msg.MsgType != "markdown" {
+			t.Errorf("MsgType = %q, want %q", msg.MsgType, "markdown")
+		}
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		wm := webhook.Message{Status: "bogus"}
+		if _, err := tr.Translate(wm, url.Values{}); err == nil {
+			t.Error("Translate() with an unknown status, want error")
+		}
+	})
+}
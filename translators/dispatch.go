@@ -0,0 +1,200 @@
+package translators
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexrudd/am-rest-translator/metrics"
+)
+
+// Defaults used until Configure is called by main.
+const (
+	DefaultConcurrency = 100
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxAttempts = 3
+)
+
+// notifyClient is shared by every outbound request so connections to
+// vendor APIs are kept alive and reused instead of churning under load.
+var notifyClient = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+var (
+	notifySemaphore = make(chan struct{}, DefaultConcurrency)
+	notifyTimeout   = DefaultTimeout
+	notifyAttempts  = DefaultMaxAttempts
+)
+
+// jitterRand generates retry backoff jitter. It is seeded explicitly rather
+// than left to default to rand's unseeded global source (deterministically
+// seeded to 1 before go1.20), so that replicas restarted together after an
+// upstream outage don't all replay the same retry timing and hammer the
+// upstream in lockstep. rand.Rand is not safe for concurrent use, so access
+// is guarded by jitterRandMu; sendWithRetry runs concurrently across the
+// dispatch worker pool.
+var (
+	jitterRandMu sync.Mutex
+	jitterRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func jitter(n int64) time.Duration {
+	jitterRandMu.Lock()
+	defer jitterRandMu.Unlock()
+	return time.Duration(jitterRand.Int63n(n))
+}
+
+// Configure sets the bounded worker pool size, per-call timeout, and max
+// retry attempts used when dispatching OutboundRequests to vendor APIs. It
+// must be called before the server starts accepting requests.
+func Configure(concurrency int, timeout time.Duration, maxAttempts int) {
+	notifySemaphore = make(chan struct{}, concurrency)
+	notifyTimeout = timeout
+	notifyAttempts = maxAttempts
+}
+
+// dispatchResult is the outcome of sending a single OutboundRequest,
+// reported back to Alertmanager in the handler's summary body.
+type dispatchResult struct {
+	Index int    `json:"index"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// dispatch sends every OutboundRequest concurrently, bounded by the shared
+// notify semaphore, and returns one result per request in the same order.
+// ctx is derived from the inbound HTTP request so that when Alertmanager
+// gives up waiting, in-flight upstream calls are cancelled too.
+func dispatch(ctx context.Context, translator, endpoint string, outbound []OutboundRequest) []dispatchResult {
+	results := make([]dispatchResult, len(outbound))
+
+	var wg sync.WaitGroup
+	for i, o := range outbound {
+		wg.Add(1)
+		go func(i int, o OutboundRequest) {
+			defer wg.Done()
+
+			select {
+			case notifySemaphore <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = dispatchResult{Index: i, Error: ctx.Err().Error()}
+				return
+			}
+			defer func() { <-notifySemaphore }()
+
+			err := sendWithRetry(ctx, translator, endpoint, o)
+			results[i] = dispatchResult{Index: i, OK: err == nil}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, o)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// sendWithRetry sends o, retrying transient failures (5xx responses and
+// connection errors) with exponential backoff and jitter, up to
+// notifyAttempts tries.
+func sendWithRetry(ctx context.Context, translator, endpoint string, o OutboundRequest) error {
+	var err error
+	for attempt := 1; attempt <= notifyAttempts; attempt++ {
+		err = send(ctx, translator, endpoint, o)
+		if err == nil {
+			return nil
+		}
+
+		transient := true
+		if ue, ok := err.(*upstreamStatusError); ok {
+			transient = ue.statusCode/100 == 5
+		}
+		if !transient || attempt == notifyAttempts {
+			break
+		}
+
+		backoff := time.Duration(1<<uint(attempt-1)) * 100 * time.Millisecond
+		wait := backoff + jitter(int64(backoff))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// upstreamStatusError records a non-2xx response from a vendor API, so
+// sendWithRetry can tell transient (5xx) failures from permanent ones.
+type upstreamStatusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *upstreamStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from upstream: %s", e.statusCode, e.body)
+}
+
+// send issues a single OutboundRequest against endpoint (or o.URL, if set),
+// bounded by notifyTimeout, and returns an error unless the upstream
+// responds with a 2xx status code. The outcome and latency are recorded
+// against translator in the amrt_upstream_* metrics.
+func send(ctx context.Context, translator, endpoint string, o OutboundRequest) error {
+	dest := endpoint
+	if o.URL != "" {
+		dest = o.URL
+	}
+	method := o.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, dest, bytes.NewReader(o.Body))
+	if err != nil {
+		return fmt.Errorf("could not build upstream request: %s", err.Error())
+	}
+	httpReq.Header.Set("Content-Type", o.ContentType)
+
+	start := time.Now()
+	resp, err := notifyClient.Do(httpReq)
+	metrics.UpstreamDuration.WithLabelValues(translator).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.UpstreamRequestsTotal.WithLabelValues(translator, "error").Inc()
+		return fmt.Errorf("could not reach upstream: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	metrics.UpstreamRequestsTotal.WithLabelValues(translator, strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &upstreamStatusError{statusCode: resp.StatusCode, body: string(body)}
+	}
+	return nil
+}
+
+// summaryBody marshals results into the JSON body returned to Alertmanager.
+func summaryBody(results []dispatchResult) []byte {
+	b, err := json.Marshal(struct {
+		Results []dispatchResult `json:"results"`
+	}{results})
+	if err != nil {
+		return []byte(`{"results":[]}`)
+	}
+	return b
+}
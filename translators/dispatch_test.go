@@ -0,0 +1,254 @@
+package translators
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+// fakeTranslator is a minimal Translator used to exercise register()'s
+// status-code selection against a real upstream server.
+type fakeTranslator struct {
+	path     string
+	endpoint string
+	requests []OutboundRequest
+}
+
+func (f fakeTranslator) Path() string { return f.path }
+
+func (f fakeTranslator) Endpoint(query url.Values) (string, error) { return f.endpoint, nil }
+
+func (f fakeTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	return f.requests, nil
+}
+
+// withTestConfigure runs fn with a fast-retrying Configure applied, and
+// restores the previous worker pool settings afterwards.
+func withTestConfigure(t *testing.T, concurrency, maxAttempts int, timeout time.Duration, fn func()) {
+	t.Helper()
+	Configure(concurrency, timeout, maxAttempts)
+	defer Configure(DefaultConcurrency, DefaultTimeout, DefaultMaxAttempts)
+	fn()
+}
+
+func TestSendWithRetry(t *testing.T) {
+	t.Run("5xx retries and then succeeds", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 1, 3, time.Second, func() {
+			err := sendWithRetry(context.Background(), "test", srv.URL, OutboundRequest{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if got := atomic.LoadInt32(&attempts); got != 3 {
+				t.Errorf("attempts = %d, want 3", got)
+			}
+		})
+	})
+
+	t.Run("4xx does not retry", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 1, 3, time.Second, func() {
+			err := sendWithRetry(context.Background(), "test", srv.URL, OutboundRequest{})
+			if err == nil {
+				t.Fatal("sendWithRetry() with a 4xx response, want error")
+			}
+			if got := atomic.LoadInt32(&attempts); got != 1 {
+				t.Errorf("attempts = %d, want 1", got)
+			}
+		})
+	})
+
+	t.Run("exhausts retries on a persistent 5xx", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 1, 3, time.Second, func() {
+			err := sendWithRetry(context.Background(), "test", srv.URL, OutboundRequest{})
+			if err == nil {
+				t.Fatal("sendWithRetry() with a persistent 5xx, want error")
+			}
+			if got := atomic.LoadInt32(&attempts); got != 3 {
+				t.Errorf("attempts = %d, want 3", got)
+			}
+		})
+	})
+
+	t.Run("context cancellation mid-retry stops retrying", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 1, 5, time.Second, func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				time.Sleep(150 * time.Millisecond)
+				cancel()
+			}()
+
+			err := sendWithRetry(ctx, "test", srv.URL, OutboundRequest{})
+			if err == nil {
+				t.Fatal("sendWithRetry() with a cancelled context, want error")
+			}
+			if got := atomic.LoadInt32(&attempts); got >= 5 {
+				t.Errorf("attempts = %d, want fewer than the configured max of 5", got)
+			}
+		})
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	t.Run("all requests fail", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 10, 1, time.Second, func() {
+			results := dispatch(context.Background(), "test", srv.URL, []OutboundRequest{{}, {}})
+			for _, r := range results {
+				if r.OK {
+					t.Errorf("result = %+v, want OK=false", r)
+				}
+			}
+		})
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get(testFailParam) == "1" {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		withTestConfigure(t, 10, 1, time.Second, func() {
+			outbound := []OutboundRequest{
+				{URL: srv.URL + "?" + testFailParam + "=0"},
+				{URL: srv.URL + "?" + testFailParam + "=1"},
+			}
+			results := dispatch(context.Background(), "test", srv.URL, outbound)
+			if len(results) != 2 {
+				t.Fatalf("len(results) = %d, want 2", len(results))
+			}
+			if !results[0].OK {
+				t.Errorf("results[0] = %+v, want OK=true", results[0])
+			}
+			if results[1].OK {
+				t.Errorf("results[1] = %+v, want OK=false", results[1])
+			}
+		})
+	})
+}
+
+const testFailParam = "fail"
+
+func TestRegisterStatusSelection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get(testFailParam) == "1" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	withTestConfigure(t, 10, 1, time.Second, func() {
+		cases := []struct {
+			name     string
+			requests []OutboundRequest
+			wantCode int
+		}{
+			{
+				name:     "all failed",
+				requests: []OutboundRequest{{URL: srv.URL + "?" + testFailParam + "=1"}},
+				wantCode: http.StatusBadGateway,
+			},
+			{
+				name: "partial failure",
+				requests: []OutboundRequest{
+					{URL: srv.URL + "?" + testFailParam + "=0"},
+					{URL: srv.URL + "?" + testFailParam + "=1"},
+				},
+				wantCode: http.StatusMultiStatus,
+			},
+			{
+				name:     "all ok",
+				requests: []OutboundRequest{{URL: srv.URL + "?" + testFailParam + "=0"}},
+				wantCode: http.StatusOK,
+			},
+		}
+
+		for i, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				ft := fakeTranslator{path: "/fake-status-test-" + string(rune('a'+i)), endpoint: srv.URL, requests: c.requests}
+				register(ft)
+
+				body := strings.NewReader(`{"status":"firing"}`)
+				req := httptest.NewRequest(http.MethodPost, ft.path, body)
+				rw := httptest.NewRecorder()
+
+				Handles[ft.path](rw, req)
+
+				if rw.Code != c.wantCode {
+					t.Errorf("status = %d, want %d", rw.Code, c.wantCode)
+				}
+			})
+		}
+	})
+}
+
+func TestDispatch_ContextCancellationWhileQueued(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// A pool of size zero never admits a request: every dispatch must
+	// observe ctx.Done() instead of acquiring the semaphore.
+	withTestConfigure(t, 0, 1, time.Second, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := dispatch(ctx, "test", srv.URL, []OutboundRequest{{}})
+		if len(results) != 1 {
+			t.Fatalf("len(results) = %d, want 1", len(results))
+		}
+		if results[0].OK {
+			t.Error("result with a cancelled context, want OK=false")
+		}
+		if results[0].Error == "" {
+			t.Error("result with a cancelled context, want a non-empty error")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package translators
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func init() {
+	register(feishuTranslator{})
+}
+
+const feishuRouteParam = "route"
+
+// feishuRoute holds the cached secret files for a single named Feishu
+// route, as configured by the operator. secret is nil when the route has
+// no signing secret configured.
+type feishuRoute struct {
+	accessToken *secretfile.File
+	secret      *secretfile.File
+}
+
+var feishuRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]feishuRoute
+}{routes: make(map[string]feishuRoute)}
+
+// configureFeishuRoutes registers the Feishu routes from cfg, replacing any
+// previously registered routes. Called from Init.
+func configureFeishuRoutes(cfg *config.Config) {
+	routes := make(map[string]feishuRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		fr := feishuRoute{accessToken: secretfile.New(r.AccessTokenFile)}
+		if r.SecretFile != "" {
+			fr.secret = secretfile.New(r.SecretFile)
+		}
+		routes[name] = fr
+	}
+
+	feishuRoutes.mu.Lock()
+	feishuRoutes.routes = routes
+	feishuRoutes.mu.Unlock()
+}
+
+// feishuPost - a "post" (rich text) message for Feishu's custom bot webhook.
+// https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot
+type feishuPost struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Sign      string `json:"sign,omitempty"`
+	MsgType   string `json:"msg_type"`
+	Content   struct {
+		Post struct {
+			ZhCn struct {
+				Title   string       `json:"title"`
+				Content [][]feishuKV `json:"content"`
+			} `json:"zh_cn"`
+		} `json:"post"`
+	} `json:"content"`
+}
+
+type feishuKV struct {
+	Tag  string `json:"tag"`
+	Text string `json:"text"`
+}
+
+// feishuTranslator implements Translator for Feishu's custom bot webhook.
+type feishuTranslator struct{}
+
+func (feishuTranslator) Path() string { return "/feishu" }
+
+func (feishuTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(feishuRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", feishuRouteParam)
+	}
+
+	feishuRoutes.mu.RLock()
+	route, ok := feishuRoutes.routes[routeName]
+	feishuRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	token, err := route.accessToken.Get()
+	if err != nil {
+		return "", fmt.Errorf("could not read access_token_file for route %s: %s", routeName, err.Error())
+	}
+
+	return "https://open.feishu.cn/open-apis/bot/v2/hook/" + url.QueryEscape(token), nil
+}
+
+// Translate renders the alert group as a single Feishu "post" message,
+// signed with the route's configured secret, if any.
+func (feishuTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	routeName := query.Get(feishuRouteParam)
+
+	feishuRoutes.mu.RLock()
+	route, ok := feishuRoutes.routes[routeName]
+	feishuRoutes.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+
+	var title string
+	var lines []string
+	switch wm.Status {
+	case "firing":
+		title = displayName + " firing"
+		for _, alert := range wm.Alerts {
+			lines = append(lines, alert.Labels["alertname"]+": "+alert.Annotations["summary"])
+		}
+	case "resolved":
+		title = displayName + " resolved"
+		lines = append(lines, "all alerts in this group have recovered")
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
+	}
+
+	var msg feishuPost
+	msg.MsgType = "post"
+	msg.Content.Post.ZhCn.Title = title
+	for _, line := range lines {
+		msg.Content.Post.ZhCn.Content = append(msg.Content.Post.ZhCn.Content, []feishuKV{{Tag: "text", Text: line}})
+	}
+
+	if route.secret != nil {
+		secret, err := route.secret.Get()
+		if err != nil {
+			return nil, fmt.Errorf("could not read secret_file for route %s: %s", routeName, err.Error())
+		}
+		ts := time.Now().Unix()
+		sign, err := feishuSign(ts, secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign feishu message: %s", err.Error())
+		}
+		msg.Timestamp = strconv.FormatInt(ts, 10)
+		msg.Sign = sign
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feishu message: %s", err.Error())
+	}
+	return []OutboundRequest{{ContentType: "application/json", Body: b}}, nil
+}
+
+// feishuSign computes the "timestamp + "\n" + secret" HMAC-SHA256 signature
+// Feishu requires when a custom bot has signature verification enabled.
+func feishuSign(timestamp int64, secret string) (string, error) {
+	key := strconv.FormatInt(timestamp, 10) + "\n" + secret
+	h := hmac.New(sha256.New, []byte(key))
+	if _, err := h.Write([]byte{}); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
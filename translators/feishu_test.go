@@ -0,0 +1,84 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func TestFeishuTranslate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feishu")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureFeishuRoutes(&config.Config{Routes: map[string]config.Route{
+		"unsigned": {AccessTokenFile: writeSecret(t, dir, "token-a", "tok-a")},
+		"signed": {
+			AccessTokenFile: writeSecret(t, dir, "token-b", "tok-b"),
+			SecretFile:      writeSecret(t, dir, "secret-b", "shh"),
+		},
+	}})
+
+	tr := feishuTranslator{}
+	wm := webhook.Message{
+		Status:      "firing",
+		GroupLabels: webhook.KV{"alertname": "HighLatency"},
+		Alerts: []webhook.Alert{
+			{Labels: webhook.KV{"alertname": "HighLatency"}, Annotations: webhook.KV{"summary": "latency is high"}},
+		},
+	}
+
+	t.Run("unsigned route leaves sign empty", func(t *testing.T) {
+		reqs, err := tr.Translate(wm, url.Values{feishuRouteParam: {"unsigned"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		var msg feishuPost
+		if err := json.Unmarshal(reqs[0].Body, &msg); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if msg.Sign != "" || msg.Timestamp != "" {
+			t.Errorf("unsigned route produced Sign=%q Timestamp=%q, want both empty", msg.Sign, msg.Timestamp)
+		}
+	})
+
+	t.Run("signed route sets a matching sign", func(t *testing.T) {
+		reqs, err := tr.Translate(wm, url.Values{feishuRouteParam: {"signed"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		var msg feishuPost
+		if err := json.Unmarshal(reqs[0].Body, &msg); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if msg.Sign == "" || msg.Timestamp == "" {
+			t.Fatal("signed route left Sign or Timestamp empty")
+		}
+
+		ts, err := strconv.ParseInt(msg.Timestamp, 10, 64)
+		if err != nil {
+			t.Fatalf("could not parse timestamp: %s", err.Error())
+		}
+		want, err := feishuSign(ts, "shh")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if msg.Sign != want {
+			t.Errorf("Sign = %q, want %q", msg.Sign, want)
+		}
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		if _, err := tr.Translate(wm, url.Values{feishuRouteParam: {"does-not-exist"}}); err == nil {
+			t.Error("Translate() with an unknown route, want error")
+		}
+	})
+}
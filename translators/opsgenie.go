@@ -0,0 +1,140 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func init() {
+	register(opsgenieTranslator{})
+}
+
+const opsgenieRouteParam = "route"
+
+// opsgenieCreateURL and opsgenieCloseURL are OpsGenie's v1 JSON API
+// endpoints for raising and closing an alert, respectively.
+const (
+	opsgenieCreateURL = "https://api.opsgenie.com/v1/json/alert"
+	opsgenieCloseURL  = "https://api.opsgenie.com/v1/json/alert/close"
+)
+
+// opsgenieRoute holds the cached secret file for a single named OpsGenie
+// route, as configured by the operator.
+type opsgenieRoute struct {
+	apiKey *secretfile.File
+}
+
+var opsgenieRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]opsgenieRoute
+}{routes: make(map[string]opsgenieRoute)}
+
+// configureOpsgenieRoutes registers the OpsGenie routes from cfg, replacing
+// any previously registered routes. Called from Init.
+func configureOpsgenieRoutes(cfg *config.Config) {
+	routes := make(map[string]opsgenieRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		routes[name] = opsgenieRoute{apiKey: secretfile.New(r.APIKeyFile)}
+	}
+
+	opsgenieRoutes.mu.Lock()
+	opsgenieRoutes.routes = routes
+	opsgenieRoutes.mu.Unlock()
+}
+
+// opsgenieAlert - the body for OpsGenie's "Create Alert" API.
+// https://docs.opsgenie.com/docs/alert-api#create-alert
+type opsgenieAlert struct {
+	APIKey      string            `json:"apiKey"`
+	Alias       string            `json:"alias"`
+	Message     string            `json:"message"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// opsgenieClose - the body for OpsGenie's "Close Alert" API.
+// https://docs.opsgenie.com/docs/alert-api#close-alert
+type opsgenieClose struct {
+	APIKey string `json:"apiKey"`
+	Alias  string `json:"alias"`
+	Source string `json:"source,omitempty"`
+}
+
+// opsgenieTranslator implements Translator for OpsGenie's v1 JSON alert API.
+type opsgenieTranslator struct{}
+
+func (opsgenieTranslator) Path() string { return "/opsgenie" }
+
+func (opsgenieTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(opsgenieRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", opsgenieRouteParam)
+	}
+
+	opsgenieRoutes.mu.RLock()
+	_, ok := opsgenieRoutes.routes[routeName]
+	opsgenieRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	return opsgenieCreateURL, nil
+}
+
+// Translate creates one alert per firing issue in the group, or closes the
+// group's alert when it resolves.
+func (opsgenieTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	routeName := query.Get(opsgenieRouteParam)
+
+	opsgenieRoutes.mu.RLock()
+	route, ok := opsgenieRoutes.routes[routeName]
+	opsgenieRoutes.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	apiKey, err := route.apiKey.Get()
+	if err != nil {
+		return nil, fmt.Errorf("could not read api_key_file for route %s: %s", routeName, err.Error())
+	}
+	alias := string(wm.GroupKey)
+	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+
+	switch wm.Status {
+	case "firing":
+		reqs := make([]OutboundRequest, 0, len(wm.Alerts))
+		for _, alert := range wm.Alerts {
+			oa := opsgenieAlert{
+				APIKey:      apiKey,
+				Alias:       alias,
+				Message:     displayName,
+				Description: alert.Annotations["description"],
+				Source:      "Prometheus Alertmanager",
+				Details:     alert.Labels,
+			}
+			b, err := json.Marshal(oa)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal opsgenie alert: %s", err.Error())
+			}
+			reqs = append(reqs, OutboundRequest{ContentType: "application/json", Body: b})
+		}
+		return reqs, nil
+	case "resolved":
+		oc := opsgenieClose{APIKey: apiKey, Alias: alias, Source: "Prometheus Alertmanager"}
+		b, err := json.Marshal(oc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal opsgenie close: %s", err.Error())
+		}
+		return []OutboundRequest{{URL: opsgenieCloseURL, ContentType: "application/json", Body: b}}, nil
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
+	}
+}
@@ -0,0 +1,128 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func writeSecret(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("could not write secret file: %s", err.Error())
+	}
+	return path
+}
+
+func TestOpsgenieEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opsgenie")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureOpsgenieRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {APIKeyFile: writeSecret(t, dir, "api_key", "abc123")},
+	}})
+
+	tr := opsgenieTranslator{}
+
+	if _, err := tr.Endpoint(url.Values{}); err == nil {
+		t.Error("Endpoint() without a route, want error")
+	}
+	if _, err := tr.Endpoint(url.Values{opsgenieRouteParam: {"does-not-exist"}}); err == nil {
+		t.Error("Endpoint() with an unknown route, want error")
+	}
+
+	endpoint, err := tr.Endpoint(url.Values{opsgenieRouteParam: {"team-a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if endpoint != opsgenieCreateURL {
+		t.Errorf("Endpoint() = %q, want %q", endpoint, opsgenieCreateURL)
+	}
+}
+
+func TestOpsgenieTranslate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opsgenie")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureOpsgenieRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {APIKeyFile: writeSecret(t, dir, "api_key", "abc123")},
+	}})
+
+	tr := opsgenieTranslator{}
+	query := url.Values{opsgenieRouteParam: {"team-a"}}
+
+	t.Run("firing", func(t *testing.T) {
+		wm := webhook.Message{
+			Status:   "firing",
+			GroupKey: "{}/{alertname=\"HighLatency\"}",
+			Alerts: []webhook.Alert{
+				{Annotations: webhook.KV{"description": "latency is high"}},
+				{Annotations: webhook.KV{"description": "latency is very high"}},
+			},
+		}
+		reqs, err := tr.Translate(wm, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 2 {
+			t.Fatalf("len(reqs) = %d, want 2", len(reqs))
+		}
+		var oa opsgenieAlert
+		if err := json.Unmarshal(reqs[0].Body, &oa); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if oa.APIKey != "abc123" {
+			t.Errorf("APIKey = %q, want %q", oa.APIKey, "abc123")
+		}
+		if oa.Alias != string(wm.GroupKey) {
+			t.Errorf("Alias = %q, want %q", oa.Alias, wm.GroupKey)
+		}
+		if oa.Description != "latency is high" {
+			t.Errorf("Description = %q, want %q", oa.Description, "latency is high")
+		}
+		if reqs[0].URL != "" {
+			t.Errorf("firing request URL = %q, want endpoint default", reqs[0].URL)
+		}
+	})
+
+	t.Run("resolved", func(t *testing.T) {
+		wm := webhook.Message{Status: "resolved", GroupKey: "{}/{alertname=\"HighLatency\"}"}
+		reqs, err := tr.Translate(wm, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		if reqs[0].URL != opsgenieCloseURL {
+			t.Errorf("resolved request URL = %q, want %q", reqs[0].URL, opsgenieCloseURL)
+		}
+		var oc opsgenieClose
+		if err := json.Unmarshal(reqs[0].Body, &oc); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if oc.APIKey != "abc123" {
+			t.Errorf("APIKey = %q, want %q", oc.APIKey, "abc123")
+		}
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		wm := webhook.Message{Status: "bogus"}
+		if _, err := tr.Translate(wm, query); err == nil {
+			t.Error("Translate() with an unknown status, want error")
+		}
+	})
+}
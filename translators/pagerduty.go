@@ -0,0 +1,142 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func init() {
+	register(pagerdutyTranslator{})
+}
+
+const (
+	pagerdutyRouteParam = "route"
+	pagerdutyEventsURL  = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// pagerdutyRoute holds the cached secret file for a single named PagerDuty
+// route, as configured by the operator.
+type pagerdutyRoute struct {
+	routingKey *secretfile.File
+}
+
+var pagerdutyRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]pagerdutyRoute
+}{routes: make(map[string]pagerdutyRoute)}
+
+// configurePagerdutyRoutes registers the PagerDuty routes from cfg,
+// replacing any previously registered routes. Called from Init.
+func configurePagerdutyRoutes(cfg *config.Config) {
+	routes := make(map[string]pagerdutyRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		routes[name] = pagerdutyRoute{routingKey: secretfile.New(r.RoutingKeyFile)}
+	}
+
+	pagerdutyRoutes.mu.Lock()
+	pagerdutyRoutes.routes = routes
+	pagerdutyRoutes.mu.Unlock()
+}
+
+// pagerdutyPayload - the "payload" object of a PagerDuty Events API v2 event.
+// https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+type pagerdutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// pagerdutyEvent - the body of a PagerDuty Events API v2 request. Action is
+// one of "trigger", "acknowledge", or "resolve".
+type pagerdutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerdutyPayload `json:"payload,omitempty"`
+}
+
+// pagerdutyTranslator implements Translator for the PagerDuty Events API v2.
+type pagerdutyTranslator struct{}
+
+func (pagerdutyTranslator) Path() string { return "/pagerduty" }
+
+func (pagerdutyTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(pagerdutyRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", pagerdutyRouteParam)
+	}
+
+	pagerdutyRoutes.mu.RLock()
+	_, ok := pagerdutyRoutes.routes[routeName]
+	pagerdutyRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	return pagerdutyEventsURL, nil
+}
+
+// Translate triggers one event per firing issue in the group, or resolves
+// the group's event when it resolves.
+func (pagerdutyTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	routeName := query.Get(pagerdutyRouteParam)
+
+	pagerdutyRoutes.mu.RLock()
+	route, ok := pagerdutyRoutes.routes[routeName]
+	pagerdutyRoutes.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	routingKey, err := route.routingKey.Get()
+	if err != nil {
+		return nil, fmt.Errorf("could not read routing_key_file for route %s: %s", routeName, err.Error())
+	}
+	dedupKey := string(wm.GroupKey)
+	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+
+	switch wm.Status {
+	case "firing":
+		reqs := make([]OutboundRequest, 0, len(wm.Alerts))
+		for _, alert := range wm.Alerts {
+			severity := "critical"
+			if alert.Labels["severity"] != "" {
+				severity = alert.Labels["severity"]
+			}
+			ev := pagerdutyEvent{
+				RoutingKey:  routingKey,
+				EventAction: "trigger",
+				DedupKey:    dedupKey,
+				Payload: pagerdutyPayload{
+					Summary:       displayName,
+					Source:        alert.GeneratorURL,
+					Severity:      severity,
+					CustomDetails: alert.Annotations,
+				},
+			}
+			b, err := json.Marshal(ev)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal pagerduty event: %s", err.Error())
+			}
+			reqs = append(reqs, OutboundRequest{ContentType: "application/json", Body: b})
+		}
+		return reqs, nil
+	case "resolved":
+		ev := pagerdutyEvent{RoutingKey: routingKey, EventAction: "resolve", DedupKey: dedupKey}
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal pagerduty event: %s", err.Error())
+		}
+		return []OutboundRequest{{ContentType: "application/json", Body: b}}, nil
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
+	}
+}
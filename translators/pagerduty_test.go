@@ -0,0 +1,92 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func TestPagerdutyTranslate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pagerduty")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configurePagerdutyRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {RoutingKeyFile: writeSecret(t, dir, "routing_key", "R0UT1NGKEY")},
+	}})
+
+	tr := pagerdutyTranslator{}
+	query := url.Values{pagerdutyRouteParam: {"team-a"}}
+
+	if _, err := tr.Endpoint(url.Values{pagerdutyRouteParam: {"does-not-exist"}}); err == nil {
+		t.Error("Endpoint() with an unknown route, want error")
+	}
+
+	t.Run("firing", func(t *testing.T) {
+		wm := webhook.Message{
+			Status:   "firing",
+			GroupKey: "{}/{alertname=\"HighLatency\"}",
+			Alerts: []webhook.Alert{
+				{Labels: webhook.KV{"severity": "warning"}, GeneratorURL: "http://prom/g/1"},
+			},
+		}
+		reqs, err := tr.Translate(wm, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var ev pagerdutyEvent
+		if err := json.Unmarshal(reqs[0].Body, &ev); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if ev.RoutingKey != "R0UT1NGKEY" {
+			t.Errorf("RoutingKey = %q, want %q", ev.RoutingKey, "R0UT1NGKEY")
+		}
+		if ev.EventAction != "trigger" {
+			t.Errorf("EventAction = %q, want %q", ev.EventAction, "trigger")
+		}
+		if ev.DedupKey != string(wm.GroupKey) {
+			t.Errorf("DedupKey = %q, want %q", ev.DedupKey, wm.GroupKey)
+		}
+		if ev.Payload.Severity != "warning" {
+			t.Errorf("Payload.Severity = %q, want %q", ev.Payload.Severity, "warning")
+		}
+	})
+
+	t.Run("resolved", func(t *testing.T) {
+		wm := webhook.Message{Status: "resolved", GroupKey: "{}/{alertname=\"HighLatency\"}"}
+		reqs, err := tr.Translate(wm, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var ev pagerdutyEvent
+		if err := json.Unmarshal(reqs[0].Body, &ev); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if ev.EventAction != "resolve" {
+			t.Errorf("EventAction = %q, want %q", ev.EventAction, "resolve")
+		}
+		if ev.DedupKey != string(wm.GroupKey) {
+			t.Errorf("DedupKey = %q, want %q", ev.DedupKey, wm.GroupKey)
+		}
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		wm := webhook.Message{Status: "bogus"}
+		if _, err := tr.Translate(wm, query); err == nil {
+			t.Error("Translate() with an unknown status, want error")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+// Package tmpl renders the free-text fields of outbound vendor
+// notifications (eg VictorOps' state_message and entity_display_name) from
+// Go templates, so operators can customise message shape per team without
+// recompiling the translator. Templates are loaded from a directory at
+// startup and can be reloaded on demand (eg on SIGHUP), falling back to
+// built-in defaults for any template that has no override file.
+package tmpl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+// Names of the templates operators may override by placing a file of that
+// name in --templates-dir.
+const (
+	VictoropsStateMessage      = "victorops.state_message.tmpl"
+	VictoropsEntityDisplayName = "victorops.entity_display_name.tmpl"
+	VictoropsEntityID          = "victorops.entity_id.tmpl"
+)
+
+// defaults reproduce the translator's pre-template behaviour, so an empty
+// --templates-dir is a no-op.
+var defaults = map[string]string{
+	VictoropsStateMessage: `{{ range $k, $v := .Alert.Annotations }}{{ $k }}: {{ $v }}
+{{ end }}{{ range $k, $v := .Alert.Labels }}{{ $k }}: {{ $v }}
+{{ end }}Prometheus: {{ .GeneratorURL }}
+Alertmanager: {{ .ExternalURL }}`,
+	VictoropsEntityDisplayName: `{{ .GroupLabelsJoined }}`,
+	VictoropsEntityID:          `{{ .GroupKey }}`,
+}
+
+// Data is the value passed to templates. Alert is populated when rendering
+// a per-alert template (eg VictoropsStateMessage); it is the zero value
+// when rendering a per-group template (eg VictoropsEntityDisplayName).
+type Data struct {
+	Receiver          string
+	Status            string
+	GroupKey          string
+	GroupLabels       webhook.KV
+	CommonLabels      webhook.KV
+	CommonAnnotations webhook.KV
+	ExternalURL       string
+	Alerts            []webhook.Alert
+	Alert             webhook.Alert
+}
+
+// GroupLabelsJoined returns the group labels' values joined with ":", eg
+// for use as a human-readable entity display name.
+func (d Data) GroupLabelsJoined() string {
+	return strings.Join(d.GroupLabels.Values(), ":")
+}
+
+// GeneratorURL returns the current alert's GeneratorURL, so a single
+// template works whether it's rendering a per-alert or per-group field.
+func (d Data) GeneratorURL() string {
+	return d.Alert.GeneratorURL
+}
+
+// Store holds the compiled templates used to render outbound vendor
+// notification fields. It is safe for concurrent use; call Reload after
+// the files in its directory have changed (eg on SIGHUP) to pick them up.
+type Store struct {
+	dir string
+
+	mu   sync.RWMutex
+	tmpl map[string]*template.Template
+}
+
+// New loads templates from dir, falling back to the built-in default for
+// any template that has no <name> file in dir. dir may be empty, in which
+// case the defaults are used for everything.
+func New(dir string) (*Store, error) {
+	s := &Store{dir: dir}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-parses every template from disk, or from the built-in default
+// when dir is empty or has no override file for that template.
+func (s *Store) Reload() error {
+	tmpl := make(map[string]*template.Template, len(defaults))
+	for name, def := range defaults {
+		src := def
+		if s.dir != "" {
+			b, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+			if err == nil {
+				src = string(b)
+			} else if !os.IsNotExist(err) {
+				return fmt.Errorf("could not read template %s: %s", name, err.Error())
+			}
+		}
+
+		t, err := template.New(name).Parse(src)
+		if err != nil {
+			return fmt.Errorf("could not parse template %s: %s", name, err.Error())
+		}
+		tmpl[name] = t
+	}
+
+	s.mu.Lock()
+	s.tmpl = tmpl
+	s.mu.Unlock()
+	return nil
+}
+
+// Execute renders the named template against data.
+func (s *Store) Execute(name string, data Data) (string, error) {
+	s.mu.RLock()
+	t, ok := s.tmpl[name]
+	s.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("could not render template %q: %s", name, err.Error())
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,168 @@
+package tmpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func TestNewWithEmptyDirUsesDefaults(t *testing.T) {
+	s, err := New("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got, err := s.Execute(VictoropsEntityID, Data{GroupKey: "{}/{alertname=\"HighLatency\"}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "{}/{alertname=\"HighLatency\"}" {
+		t.Errorf("Execute(VictoropsEntityID) = %q, want the group key unchanged", got)
+	}
+}
+
+func TestNewFallsBackToDefaultForMissingOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpl")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// Override only one of the three templates; the other two must still
+	// render using their built-in defaults.
+	if err := ioutil.WriteFile(filepath.Join(dir, VictoropsEntityID), []byte("custom-{{ .GroupKey }}"), 0600); err != nil {
+		t.Fatalf("could not write override: %s", err.Error())
+	}
+
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	data := Data{GroupKey: "abc", GroupLabels: webhook.KV{"team": "sre"}}
+
+	gotID, err := s.Execute(VictoropsEntityID, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if gotID != "custom-abc" {
+		t.Errorf("Execute(VictoropsEntityID) = %q, want %q", gotID, "custom-abc")
+	}
+
+	gotName, err := s.Execute(VictoropsEntityDisplayName, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if gotName != "sre" {
+		t.Errorf("Execute(VictoropsEntityDisplayName) = %q, want %q (default)", gotName, "sre")
+	}
+}
+
+func TestReloadPicksUpChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpl")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, VictoropsEntityID)
+	if err := ioutil.WriteFile(path, []byte("v1-{{ .GroupKey }}"), 0600); err != nil {
+		t.Fatalf("could not write override: %s", err.Error())
+	}
+
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got, err := s.Execute(VictoropsEntityID, Data{GroupKey: "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "v1-abc" {
+		t.Errorf("Execute(VictoropsEntityID) = %q, want %q", got, "v1-abc")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("v2-{{ .GroupKey }}"), 0600); err != nil {
+		t.Fatalf("could not write override: %s", err.Error())
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got, err = s.Execute(VictoropsEntityID, Data{GroupKey: "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if got != "v2-abc" {
+		t.Errorf("Execute(VictoropsEntityID) after Reload = %q, want %q", got, "v2-abc")
+	}
+}
+
+func TestReloadRejectsInvalidTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpl")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	path := filepath.Join(dir, VictoropsEntityID)
+	if err := ioutil.WriteFile(path, []byte("{{ .Broken"), 0600); err != nil {
+		t.Fatalf("could not write override: %s", err.Error())
+	}
+
+	if err := s.Reload(); err == nil {
+		t.Error("Reload() with an invalid template, want error")
+	}
+}
+
+func TestExecuteUnknownTemplate(t *testing.T) {
+	s, err := New("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if _, err := s.Execute("does-not-exist", Data{}); err == nil {
+		t.Error("Execute() of an unknown template, want error")
+	}
+}
+
+func TestVictoropsStateMessageEndToEnd(t *testing.T) {
+	s, err := New("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	data := Data{
+		ExternalURL: "http://alertmanager.example.com",
+		Alert: webhook.Alert{
+			Labels:       webhook.KV{"alertname": "HighLatency"},
+			Annotations:  webhook.KV{"summary": "latency is high"},
+			GeneratorURL: "http://prometheus.example.com/graph",
+		},
+	}
+
+	got, err := s.Execute(VictoropsStateMessage, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	for _, want := range []string{
+		"summary: latency is high",
+		"alertname: HighLatency",
+		"Prometheus: http://prometheus.example.com/graph",
+		"Alertmanager: http://alertmanager.example.com",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Execute(VictoropsStateMessage) = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -1,6 +1,160 @@
 package translators
 
-import "net/http"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/metrics"
+	"github.com/alexrudd/am-rest-translator/translators/tmpl"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+// Init registers every vendor backend's routes from cfg and the message
+// templates from t, replacing any previously registered routes. It must be
+// called before the handler serves requests.
+func Init(cfg *config.Config, t *tmpl.Store) {
+	configureVictoropsRoutes(cfg)
+	configureOpsgenieRoutes(cfg)
+	configurePagerdutyRoutes(cfg)
+	configureDingtalkRoutes(cfg)
+	configureFeishuRoutes(cfg)
+	configureWecomRoutes(cfg)
+	templates = t
+}
+
+// OutboundRequest is a single HTTP request that must be sent to a downstream
+// vendor API in order to notify it of an Alertmanager alert.
+type OutboundRequest struct {
+
+	// Method is the HTTP method to use. Defaults to POST when empty.
+	Method string
+
+	// URL overrides the endpoint returned by Translator.Endpoint for this
+	// particular request (eg. a vendor's separate "close alert" endpoint).
+	// Leave empty to post to the endpoint as-is.
+	URL string
+
+	// ContentType is sent as the request's Content-Type header.
+	ContentType string
+
+	// Body is the raw request body to send.
+	Body []byte
+}
+
+// Translator converts an Alertmanager webhook payload into the outbound
+// requests needed to notify a specific vendor, and registers itself on a
+// unique path under Handles.
+type Translator interface {
+
+	// Path returns the URL path this translator is served on, eg "/opsgenie".
+	Path() string
+
+	// Endpoint returns the vendor API URL to post to for a given incoming
+	// request, built from the query parameters Alertmanager was configured
+	// to send the webhook with (eg a route name).
+	Endpoint(query url.Values) (string, error)
+
+	// Translate converts an Alertmanager webhook payload, and the query
+	// parameters it arrived with, into the requests that must be sent to
+	// Endpoint to notify the vendor.
+	Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error)
+}
 
 // Handles - a map of unique paths to their http handlers
 var Handles = make(map[string]func(http.ResponseWriter, *http.Request))
+
+// register builds the generic translation handler for t and adds it to
+// Handles under t.Path(). It is called from the init() of each translator.
+func register(t Translator) {
+	translator := t.Path()
+
+	Handles[translator] = func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		log.Debugf("Recieved %s translation request: %s", translator, req.URL.Path)
+
+		decoder := json.NewDecoder(req.Body)
+		var wm webhook.Message
+		if err := decoder.Decode(&wm); err != nil {
+			log.Errorf("Could not decode Alertmanager request body: %s", err.Error())
+			metrics.DecodeErrorsTotal.Inc()
+			metrics.RequestsTotal.WithLabelValues(translator, "decode_error").Inc()
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(rw, "Could not decode Alertmanager request body: %s", err.Error())
+			return
+		} else if wm.Status == "" {
+			log.Errorf("Missing fields in request body")
+			metrics.DecodeErrorsTotal.Inc()
+			metrics.RequestsTotal.WithLabelValues(translator, "decode_error").Inc()
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(rw, "Missing fields in request body")
+			return
+		}
+
+		logEntry := log.WithFields(log.Fields{
+			"translator": translator,
+			"group_key":  string(wm.GroupKey),
+			"receiver":   wm.Receiver,
+			"status":     wm.Status,
+			"alerts":     len(wm.Alerts),
+		})
+
+		endpoint, err := t.Endpoint(req.URL.Query())
+		if err != nil {
+			log.Errorf("Could not resolve %s endpoint: %s", translator, err.Error())
+			metrics.RequestsTotal.WithLabelValues(translator, "bad_request").Inc()
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(rw, "could not resolve endpoint: %s", err.Error())
+			return
+		}
+
+		outbound, err := t.Translate(wm, req.URL.Query())
+		if err != nil {
+			log.Errorf("Could not translate request for %s: %s", translator, err.Error())
+			metrics.RequestsTotal.WithLabelValues(translator, "internal_error").Inc()
+			rw.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(rw, "could not translate request: %s", err.Error())
+			return
+		}
+
+		if len(outbound) == 0 {
+			metrics.RequestsTotal.WithLabelValues(translator, "ok").Inc()
+			logEntry.WithField("latency", time.Since(start)).Info("Translation request handled")
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+
+		metrics.AlertsForwardedTotal.WithLabelValues(translator, wm.Status).Add(float64(len(wm.Alerts)))
+
+		results := dispatch(req.Context(), translator, endpoint, outbound)
+
+		failed := 0
+		for _, r := range results {
+			if !r.OK {
+				failed++
+			}
+		}
+
+		status := "ok"
+		switch failed {
+		case 0:
+			rw.WriteHeader(http.StatusOK)
+		case len(results):
+			status = "upstream_error"
+			log.Errorf("All %d notifications failed via %s", failed, translator)
+			rw.WriteHeader(http.StatusBadGateway)
+		default:
+			status = "partial_failure"
+			log.Errorf("%d of %d notifications failed via %s", failed, len(results), translator)
+			rw.WriteHeader(http.StatusMultiStatus)
+		}
+		metrics.RequestsTotal.WithLabelValues(translator, status).Inc()
+		logEntry.WithField("latency", time.Since(start)).Info("Translation request handled")
+		rw.Write(summaryBody(results))
+	}
+}
@@ -1,17 +1,16 @@
 package translators
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"strconv"
-	"strings"
+	"net/url"
+	"sync"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
-
-	"github.com/prometheus/alertmanager/notify"
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/translators/tmpl"
+	"github.com/alexrudd/am-rest-translator/webhook"
 )
 
 // victoropsPost - The VictorOps REST Endpoint accepts alerts from any source
@@ -58,193 +57,152 @@ type victoropsPost struct {
 	AckAuthor string `json:"ack_author,omitempty"`
 }
 
-// victoropsResponse - The HTTP result code will indicate success or failure,
-// with the following JSON values in the response body
-type victoropsResponse struct {
-
-	// Result - "success" or "failure"
-	Result string `json:"result"`
+func init() {
+	register(victoropsTranslator{})
+}
 
-	// EntityID - The id passed in with the POST request, or the id randomly
-	// assigned by VictorOps. You should continue to pass us this id for
-	// subsequent alerts that pertain to the same incident.
-	EntityID string `json:"entity_id"`
+const victoropsRouteParam = "route"
 
-	// Message - Error message (if any)
-	Message string `json:"message,omitempty"`
+// victoropsRoute holds the cached secret files for a single named VictorOps
+// route, as configured by the operator.
+type victoropsRoute struct {
+	apiKey     *secretfile.File
+	routingKey *secretfile.File
 }
 
-func init() {
-	Handles["/victorops"] = victorops
+var victoropsRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]victoropsRoute
+}{routes: make(map[string]victoropsRoute)}
+
+// templates renders the state_message, entity_display_name, and entity_id
+// fields of outbound VictorOps posts. It is set by Init and reloaded by
+// main on SIGHUP.
+var templates *tmpl.Store
+
+// configureVictoropsRoutes registers the VictorOps routes from cfg,
+// replacing any previously registered routes. Called from Init.
+func configureVictoropsRoutes(cfg *config.Config) {
+	routes := make(map[string]victoropsRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		routes[name] = victoropsRoute{
+			apiKey:     secretfile.New(r.APIKeyFile),
+			routingKey: secretfile.New(r.RoutingKeyFile),
+		}
+	}
+
+	victoropsRoutes.mu.Lock()
+	victoropsRoutes.routes = routes
+	victoropsRoutes.mu.Unlock()
 }
 
-const (
-	victoropsApikeyParam     = "api_key"
-	victoropsRoutingkeyParam = "routing_key"
-)
+// victoropsTranslator implements Translator for the VictorOps REST
+// Integration API.
+type victoropsTranslator struct{}
 
-// victorops(rw http.ResponseWriter, req *http.Request)
-// the POST handle for Alertmanager translation to VictorOps
-func victorops(rw http.ResponseWriter, req *http.Request) {
-	log.Debugf("Recieved VictorOps translation request: %s", req.URL)
-	// Attempt decode
-	decoder := json.NewDecoder(req.Body)
-	var wm notify.WebhookMessage
-	err := decoder.Decode(&wm)
-	if err != nil {
-		log.Errorf("Could not decode Alertmanager request body: %s", err.Error())
-		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "Could not decode Alertmanager request body: %s", err.Error())
-		return
-	} else if wm.Data == nil {
-		log.Errorf("Missing fields in request body")
-		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "Missing fields request body")
-		return
+func (victoropsTranslator) Path() string { return "/victorops" }
+
+// Endpoint looks up the named route from query, reads its cached secret
+// files, and builds the VictorOps alert ingestion URL for it.
+func (victoropsTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(victoropsRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", victoropsRouteParam)
+	}
+
+	victoropsRoutes.mu.RLock()
+	route, ok := victoropsRoutes.routes[routeName]
+	victoropsRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
 	}
-	// Extract query params
-	apiKey := ""
-	routingKey := ""
-	if val, ok := req.URL.Query()[victoropsApikeyParam]; ok && len(val) > 0 {
-		apiKey = val[0]
+
+	apiKey, err := route.apiKey.Get()
+	if err != nil {
+		return "", fmt.Errorf("could not read api_key_file for route %s: %s", routeName, err.Error())
 	}
-	if val, ok := req.URL.Query()[victoropsRoutingkeyParam]; ok && len(val) > 0 {
-		routingKey = val[0]
+	routingKey, err := route.routingKey.Get()
+	if err != nil {
+		return "", fmt.Errorf("could not read routing_key_file for route %s: %s", routeName, err.Error())
 	}
 
-	// Validate query params
-	if apiKey == "" || routingKey == "" {
-		log.Errorf("Missing request query parameters")
-		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "requires query parameters '%s' and '%s'", victoropsApikeyParam, victoropsRoutingkeyParam)
-		return
+	return "https://alert.victorops.com/integrations/generic/20131114/alert/" + apiKey + "/" + routingKey, nil
+}
+
+// Translate converts an Alertmanager webhook.Message into one victoropsPost
+// per firing alert, or a single RECOVERY victoropsPost when the group
+// resolves. The entity_id, entity_display_name, and (per-alert)
+// state_message fields are rendered from templates.
+func (victoropsTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	groupData := tmpl.Data{
+		Receiver:          wm.Receiver,
+		Status:            wm.Status,
+		GroupKey:          string(wm.GroupKey),
+		GroupLabels:       wm.GroupLabels,
+		CommonLabels:      wm.CommonLabels,
+		CommonAnnotations: wm.CommonAnnotations,
+		ExternalURL:       wm.ExternalURL,
+		Alerts:            wm.Alerts,
 	}
 
-	// Translate Alertmanager WebhookMessage to victoropsPost
-	status := wm.Status
-	groupKey := wm.GroupKey
-	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+	entityID, err := templates.Execute(tmpl.VictoropsEntityID, groupData)
+	if err != nil {
+		return nil, err
+	}
+	displayName, err := templates.Execute(tmpl.VictoropsEntityDisplayName, groupData)
+	if err != nil {
+		return nil, err
+	}
 
-	if status == "firing" {
+	switch wm.Status {
+	case "firing":
 		// Create an alert for each issue in the group
+		reqs := make([]OutboundRequest, 0, len(wm.Alerts))
 		for _, alert := range wm.Alerts {
 			messageType := "CRITICAL"
 			// extract victorops_message_type label if defined
 			if alert.Labels["victorops_message_type"] != "" {
 				messageType = alert.Labels["victorops_message_type"]
 			}
-			// combine all annotations, alerts, and urls into state message
-			stateMessage := ""
-			for k, v := range alert.Annotations {
-				stateMessage += k + ": " + v + "\n"
-			}
-			for k, v := range alert.Labels {
-				stateMessage += k + ": " + v + "\n"
+
+			alertData := groupData
+			alertData.Alert = alert
+			stateMessage, err := templates.Execute(tmpl.VictoropsStateMessage, alertData)
+			if err != nil {
+				return nil, err
 			}
-			stateMessage += "Prometheus: " + alert.GeneratorURL + "\n"
-			stateMessage += "Alertmanager: " + wm.ExternalURL
 
-			// build alert
 			vp := victoropsPost{
 				MessageType:       messageType,
-				EntityID:          strconv.FormatUint(groupKey, 10),
+				EntityID:          entityID,
 				Timestamp:         time.Now().Unix(),
 				StateStartTime:    alert.StartsAt.Unix(),
 				StateMessage:      stateMessage,
 				MonitoringTool:    "Prometheus Alertmanager",
 				EntityDisplayName: displayName,
 			}
-			// marshall and send alert
 			b, err := json.Marshal(vp)
-			if err == nil {
-				// Post Alert
-				resp, err := http.Post("https://alert.victorops.com/integrations/generic/20131114/alert/"+apiKey+"/"+routingKey, "application/json", bytes.NewBuffer(b))
-				//resp, err := http.Post("http://localhost:8080/repeat?api="+apiKey+"&route="+routingKey, "application/json", bytes.NewBuffer(b))
-				if err != nil {
-					log.Errorf("Failed post to VictorOps REST api: %s", err.Error())
-					rw.WriteHeader(http.StatusBadGateway)
-					fmt.Fprintf(rw, "Failed post to VictorOps REST api: %s", err.Error())
-					return
-				}
-
-				// Decode response
-				decoder := json.NewDecoder(resp.Body)
-				var vr victoropsResponse
-				err = decoder.Decode(&vr)
-				if err != nil {
-					log.Errorf("Could not decode VictorOps response body: %s", err.Error())
-					//rw.WriteHeader(http.StatusBadGateway)
-					fmt.Fprintf(rw, "Could not decode VictorOps response body: %s", err.Error())
-				}
-
-				// Check Response
-				if resp.StatusCode/100 != 2 {
-					log.Errorf("Unexpected status code %v from VictorOps: %v", resp.StatusCode, vr.Message)
-					rw.WriteHeader(http.StatusBadGateway)
-					fmt.Fprintf(rw, "Unexpected status code %v from VictorOps: %v", resp.StatusCode, vr.Message)
-					return
-				}
-
-			} else {
-				// failed to marshall alert
-				log.Errorf("Failed to marshall victoropsPost: %s", err.Error())
-				rw.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprintf(rw, "Failed to marshall victoropsPost: %s", err.Error())
-				return
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal victoropsPost: %s", err.Error())
 			}
+			reqs = append(reqs, OutboundRequest{ContentType: "application/json", Body: b})
 		}
-	} else if status == "resolved" {
+		return reqs, nil
+	case "resolved":
 		vp := victoropsPost{
 			MessageType:       "RECOVERY",
-			EntityID:          strconv.FormatUint(groupKey, 10),
+			EntityID:          entityID,
 			Timestamp:         time.Now().Unix(),
 			StateMessage:      "Entity recovered",
 			MonitoringTool:    "Prometheus Alertmanager",
 			EntityDisplayName: displayName,
 		}
-		// marshall and send alert
 		b, err := json.Marshal(vp)
-		if err == nil {
-			// Post Alert
-			resp, err := http.Post("https://alert.victorops.com/integrations/generic/20131114/alert/"+apiKey+"/"+routingKey, "application/json", bytes.NewBuffer(b))
-			//resp, err := http.Post("http://localhost:8080/repeat?api="+apiKey+"&route="+routingKey, "application/json", bytes.NewBuffer(b))
-			if err != nil {
-				log.Errorf("Failed post to VictorOps REST api: %s", err.Error())
-				rw.WriteHeader(http.StatusBadGateway)
-				fmt.Fprintf(rw, "Failed post to VictorOps REST api: %s", err.Error())
-				return
-			}
-
-			// Decode response
-			decoder := json.NewDecoder(resp.Body)
-			var vr victoropsResponse
-			err = decoder.Decode(&vr)
-			if err != nil {
-				log.Errorf("Could not decode VictorOps response body: %s", err.Error())
-				rw.WriteHeader(http.StatusBadGateway)
-				fmt.Fprintf(rw, "Could not decode VictorOps response body: %s", err.Error())
-				return
-			}
-
-			// Check Response
-			if resp.StatusCode/100 != 2 {
-				log.Errorf("Unexpected status code %v from VictorOps: ", vr.Message)
-				rw.WriteHeader(http.StatusBadGateway)
-				fmt.Fprintf(rw, "Unexpected status code %v from VictorOps: ", vr.Message)
-				return
-			}
-
-		} else {
-			// failed to marshall alert
-			log.Errorf("Failed to marshall victoropsPost: %s", err.Error())
-			rw.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(rw, "Failed to marshall victoropsPost: %s", err.Error())
-			return
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal victoropsPost: %s", err.Error())
 		}
-	} else {
-		log.Errorf("Unknown Alertmanager status: %s", status)
-		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "Unknown Alertmanager status: %s", status)
-		return
+		return []OutboundRequest{{ContentType: "application/json", Body: b}}, nil
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
 	}
 }
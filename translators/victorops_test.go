@@ -0,0 +1,213 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/translators/tmpl"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+// withTestTemplates points the package-level templates Store at dir (or the
+// built-in defaults, if dir is empty) and restores the previous Store
+// afterwards.
+func withTestTemplates(t *testing.T, dir string) {
+	t.Helper()
+	s, err := tmpl.New(dir)
+	if err != nil {
+		t.Fatalf("could not load templates: %s", err.Error())
+	}
+	previous := templates
+	templates = s
+	t.Cleanup(func() { templates = previous })
+}
+
+func TestVictoropsEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "victorops")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureVictoropsRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {
+			APIKeyFile:     writeSecret(t, dir, "api_key", "apikey123"),
+			RoutingKeyFile: writeSecret(t, dir, "routing_key", "routingkey456"),
+		},
+	}})
+
+	tr := victoropsTranslator{}
+
+	if _, err := tr.Endpoint(url.Values{}); err == nil {
+		t.Error("Endpoint() without a route, want error")
+	}
+	if _, err := tr.Endpoint(url.Values{victoropsRouteParam: {"does-not-exist"}}); err == nil {
+		t.Error("Endpoint() with an unknown route, want error")
+	}
+
+	endpoint, err := tr.Endpoint(url.Values{victoropsRouteParam: {"team-a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := "https://alert.victorops.com/integrations/generic/20131114/alert/apikey123/routingkey456"
+	if endpoint != want {
+		t.Errorf("Endpoint() = %q, want %q", endpoint, want)
+	}
+}
+
+func TestVictoropsEndpointSecretfileError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "victorops")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureVictoropsRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {
+			APIKeyFile:     filepath.Join(dir, "does-not-exist"),
+			RoutingKeyFile: writeSecret(t, dir, "routing_key", "routingkey456"),
+		},
+	}})
+
+	tr := victoropsTranslator{}
+	if _, err := tr.Endpoint(url.Values{victoropsRouteParam: {"team-a"}}); err == nil {
+		t.Error("Endpoint() with an unreadable api_key_file, want error")
+	}
+}
+
+func TestVictoropsTranslate(t *testing.T) {
+	withTestTemplates(t, "")
+
+	tr := victoropsTranslator{}
+
+	t.Run("firing uses default CRITICAL message type", func(t *testing.T) {
+		wm := webhook.Message{
+			Status:   "firing",
+			GroupKey: "{}/{alertname=\"HighLatency\"}",
+			Alerts: []webhook.Alert{
+				{Labels: webhook.KV{"alertname": "HighLatency"}},
+			},
+		}
+		reqs, err := tr.Translate(wm, url.Values{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var vp victoropsPost
+		if err := json.Unmarshal(reqs[0].Body, &vp); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if vp.MessageType != "CRITICAL" {
+			t.Errorf("MessageType = %q, want %q", vp.MessageType, "CRITICAL")
+		}
+		if vp.EntityID != string(wm.GroupKey) {
+			t.Errorf("EntityID = %q, want %q", vp.EntityID, wm.GroupKey)
+		}
+	})
+
+	t.Run("firing honors victorops_message_type label override", func(t *testing.T) {
+		wm := webhook.Message{
+			Status:   "firing",
+			GroupKey: "{}/{alertname=\"HighLatency\"}",
+			Alerts: []webhook.Alert{
+				{Labels: webhook.KV{"alertname": "HighLatency", "victorops_message_type": "WARNING"}},
+			},
+		}
+		reqs, err := tr.Translate(wm, url.Values{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		var vp victoropsPost
+		if err := json.Unmarshal(reqs[0].Body, &vp); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if vp.MessageType != "WARNING" {
+			t.Errorf("MessageType = %q, want %q", vp.MessageType, "WARNING")
+		}
+	})
+
+	t.Run("resolved sends a single RECOVERY post", func(t *testing.T) {
+		wm := webhook.Message{Status: "resolved", GroupKey: "{}/{alertname=\"HighLatency\"}"}
+		reqs, err := tr.Translate(wm, url.Values{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var vp victoropsPost
+		if err := json.Unmarshal(reqs[0].Body, &vp); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if vp.MessageType != "RECOVERY" {
+			t.Errorf("MessageType = %q, want %q", vp.MessageType, "RECOVERY")
+		}
+		if vp.StateMessage != "Entity recovered" {
+			t.Errorf("StateMessage = %q, want %q", vp.StateMessage, "Entity recovered")
+		}
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		wm := webhook.Message{Status: "bogus"}
+		if _, err := tr.Translate(wm, url.Values{}); err == nil {
+			t.Error("Translate() with an unknown status, want error")
+		}
+	})
+}
+
+// TestVictoropsTranslateCustomTemplates verifies that a --templates-dir
+// override is actually rendered through victoropsTranslator.Translate and
+// lands in the marshalled victoropsPost body, not just exercised against
+// the tmpl package in isolation.
+func TestVictoropsTranslateCustomTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "victorops-templates")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	writeSecret(t, dir, tmpl.VictoropsEntityID, "custom-entity-{{ .GroupKey }}")
+	writeSecret(t, dir, tmpl.VictoropsEntityDisplayName, "custom-display-{{ .GroupLabelsJoined }}")
+	writeSecret(t, dir, tmpl.VictoropsStateMessage, "custom-state-{{ .Alert.Labels.alertname }}")
+
+	withTestTemplates(t, dir)
+
+	tr := victoropsTranslator{}
+	wm := webhook.Message{
+		Status:      "firing",
+		GroupKey:    "abc123",
+		GroupLabels: webhook.KV{"team": "sre"},
+		Alerts: []webhook.Alert{
+			{Labels: webhook.KV{"alertname": "HighLatency"}},
+		},
+	}
+
+	reqs, err := tr.Translate(wm, url.Values{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(reqs) != 1 {
+		t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+	}
+
+	var vp victoropsPost
+	if err := json.Unmarshal(reqs[0].Body, &vp); err != nil {
+		t.Fatalf("could not unmarshal body: %s", err.Error())
+	}
+	if vp.EntityID != "custom-entity-abc123" {
+		t.Errorf("EntityID = %q, want %q", vp.EntityID, "custom-entity-abc123")
+	}
+	if vp.EntityDisplayName != "custom-display-sre" {
+		t.Errorf("EntityDisplayName = %q, want %q", vp.EntityDisplayName, "custom-display-sre")
+	}
+	if vp.StateMessage != "custom-state-HighLatency" {
+		t.Errorf("StateMessage = %q, want %q", vp.StateMessage, "custom-state-HighLatency")
+	}
+}
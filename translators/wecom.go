@@ -0,0 +1,109 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/secretfile"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func init() {
+	register(wecomTranslator{})
+}
+
+const wecomRouteParam = "route"
+
+// wecomRoute holds the cached secret file for a single named WeCom route,
+// as configured by the operator.
+type wecomRoute struct {
+	key *secretfile.File
+}
+
+var wecomRoutes = struct {
+	mu     sync.RWMutex
+	routes map[string]wecomRoute
+}{routes: make(map[string]wecomRoute)}
+
+// configureWecomRoutes registers the WeCom routes from cfg, replacing any
+// previously registered routes. Called from Init.
+func configureWecomRoutes(cfg *config.Config) {
+	routes := make(map[string]wecomRoute, len(cfg.Routes))
+	for name, r := range cfg.Routes {
+		routes[name] = wecomRoute{key: secretfile.New(r.KeyFile)}
+	}
+
+	wecomRoutes.mu.Lock()
+	wecomRoutes.routes = routes
+	wecomRoutes.mu.Unlock()
+}
+
+// wecomMarkdown - the "markdown" message type for WeCom's group robot
+// webhook.
+// https://developer.work.weixin.qq.com/document/path/91770
+type wecomMarkdown struct {
+	MsgType  string `json:"msgtype"`
+	Markdown struct {
+		Content string `json:"content"`
+	} `json:"markdown"`
+}
+
+// wecomTranslator implements Translator for WeCom's group robot webhook.
+type wecomTranslator struct{}
+
+func (wecomTranslator) Path() string { return "/wecom" }
+
+func (wecomTranslator) Endpoint(query url.Values) (string, error) {
+	routeName := query.Get(wecomRouteParam)
+	if routeName == "" {
+		return "", fmt.Errorf("requires query parameter '%s'", wecomRouteParam)
+	}
+
+	wecomRoutes.mu.RLock()
+	route, ok := wecomRoutes.routes[routeName]
+	wecomRoutes.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route '%s'", routeName)
+	}
+
+	key, err := route.key.Get()
+	if err != nil {
+		return "", fmt.Errorf("could not read key_file for route %s: %s", routeName, err.Error())
+	}
+
+	return "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=" + url.QueryEscape(key), nil
+}
+
+// Translate renders the alert group as a single markdown message: a heading
+// coloured by the group's status, then a bullet per alert when firing or a
+// single recovery bullet when resolved.
+func (wecomTranslator) Translate(wm webhook.Message, query url.Values) ([]OutboundRequest, error) {
+	displayName := strings.Join(wm.GroupLabels.Values(), ":")
+
+	var lines []string
+	switch wm.Status {
+	case "firing":
+		lines = append(lines, fmt.Sprintf("### <font color=\"warning\">%s firing</font>", displayName))
+		for _, alert := range wm.Alerts {
+			lines = append(lines, fmt.Sprintf("> **%s**: %s", alert.Labels["alertname"], alert.Annotations["summary"]))
+		}
+	case "resolved":
+		lines = append(lines, fmt.Sprintf("### <font color=\"info\">%s resolved</font>", displayName))
+		lines = append(lines, "> all alerts in this group have recovered")
+	default:
+		return nil, fmt.Errorf("unknown Alertmanager status: %s", wm.Status)
+	}
+
+	msg := wecomMarkdown{MsgType: "markdown"}
+	msg.Markdown.Content = strings.Join(lines, "\n")
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal wecom message: %s", err.Error())
+	}
+	return []OutboundRequest{{ContentType: "application/json", Body: b}}, nil
+}
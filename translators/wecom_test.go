@@ -0,0 +1,68 @@
+package translators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/alexrudd/am-rest-translator/config"
+	"github.com/alexrudd/am-rest-translator/webhook"
+)
+
+func TestWecomEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wecom")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configureWecomRoutes(&config.Config{Routes: map[string]config.Route{
+		"team-a": {KeyFile: writeSecret(t, dir, "key", "wk123")},
+	}})
+
+	tr := wecomTranslator{}
+
+	if _, err := tr.Endpoint(url.Values{wecomRouteParam: {"does-not-exist"}}); err == nil {
+		t.Error("Endpoint() with an unknown route, want error")
+	}
+
+	endpoint, err := tr.Endpoint(url.Values{wecomRouteParam: {"team-a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=wk123"
+	if endpoint != want {
+		t.Errorf("Endpoint() = %q, want %q", endpoint, want)
+	}
+}
+
+func TestWecomTranslate(t *testing.T) {
+	tr := wecomTranslator{}
+
+	t.Run("resolved", func(t *testing.T) {
+		wm := webhook.Message{Status: "resolved", GroupLabels: webhook.KV{"alertname": "HighLatency"}}
+		reqs, err := tr.Translate(wm, url.Values{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("len(reqs) = %d, want 1", len(reqs))
+		}
+		var msg wecomMarkdown
+		if err := json.Unmarshal(reqs[0].Body, &msg); err != nil {
+			t.Fatalf("could not unmarshal body: %s", err.Error())
+		}
+		if msg.MsgType != "markdown" {
+			t.Errorf("MsgType = %q, want %q", msg.MsgType, "markdown")
+		}
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		wm := webhook.Message{Status: "bogus"}
+		if _, err := tr.Translate(wm, url.Values{}); err == nil {
+			t.Error("Translate() with an unknown status, want error")
+		}
+	})
+}
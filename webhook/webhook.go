@@ -0,0 +1,71 @@
+// Package webhook decodes the JSON payload Alertmanager posts to a
+// configured webhook receiver. It supports both the legacy v3 webhook
+// (numeric groupKey, no truncatedAlerts) and the v4 webhook shipped with
+// Alertmanager v0.16+ (string groupKey, truncatedAlerts, API v2 alerts),
+// without requiring operators to configure anything. The two shapes are
+// told apart by the JSON type of groupKey; the payload's "version" field is
+// decoded into Message.Version but not consulted.
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// KV is a set of labels or annotations.
+type KV map[string]string
+
+// Values returns the KV's values, in no particular order.
+func (kv KV) Values() []string {
+	vs := make([]string, 0, len(kv))
+	for _, v := range kv {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// GroupKey uniquely identifies an alert group. The v3 webhook encodes it as
+// a JSON number; the v4 webhook encodes it as a string. Both decode into
+// the same string-backed value so downstream code doesn't need to care
+// which webhook version produced it.
+type GroupKey string
+
+// UnmarshalJSON accepts either the v3 numeric or v4 string representation.
+func (g *GroupKey) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*g = GroupKey(s)
+		return nil
+	}
+	*g = GroupKey(b)
+	return nil
+}
+
+// Alert is a single Alertmanager alert.
+type Alert struct {
+	Status       string    `json:"status"`
+	Labels       KV        `json:"labels"`
+	Annotations  KV        `json:"annotations"`
+	StartsAt     time.Time `json:"startsAt"`
+	EndsAt       time.Time `json:"endsAt"`
+	GeneratorURL string    `json:"generatorURL"`
+	Fingerprint  string    `json:"fingerprint"`
+}
+
+// Message is the body Alertmanager posts to a webhook receiver, covering
+// both the v3 and v4 (API v2) payload shapes.
+type Message struct {
+	Version           string   `json:"version"`
+	GroupKey          GroupKey `json:"groupKey"`
+	TruncatedAlerts   uint64   `json:"truncatedAlerts"`
+	Status            string   `json:"status"`
+	Receiver          string   `json:"receiver"`
+	GroupLabels       KV       `json:"groupLabels"`
+	CommonLabels      KV       `json:"commonLabels"`
+	CommonAnnotations KV       `json:"commonAnnotations"`
+	ExternalURL       string   `json:"externalURL"`
+	Alerts            []Alert  `json:"alerts"`
+}
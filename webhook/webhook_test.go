@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessageUnmarshal(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		wantGroupKey GroupKey
+		wantStatus   string
+		wantAlerts   int
+	}{
+		{
+			// v3 webhook, as produced by Alertmanager <0.16: groupKey is a
+			// JSON number and there is no truncatedAlerts field.
+			name: "v3 firing",
+			payload: `{
+				"version": "3",
+				"groupKey": 1234567890,
+				"status": "firing",
+				"receiver": "victorops",
+				"groupLabels": {"alertname": "HighLatency"},
+				"commonLabels": {"alertname": "HighLatency", "severity": "critical"},
+				"commonAnnotations": {"summary": "latency is high"},
+				"externalURL": "http://alertmanager.example.com",
+				"alerts": [
+					{
+						"status": "firing",
+						"labels": {"alertname": "HighLatency", "severity": "critical"},
+						"annotations": {"summary": "latency is high"},
+						"startsAt": "2026-07-29T10:00:00Z",
+						"endsAt": "0001-01-01T00:00:00Z",
+						"generatorURL": "http://prometheus.example.com/graph"
+					}
+				]
+			}`,
+			wantGroupKey: "1234567890",
+			wantStatus:   "firing",
+			wantAlerts:   1,
+		},
+		{
+			// v4 (API v2) webhook, as produced by Alertmanager >=0.16:
+			// groupKey is a string and truncatedAlerts is present.
+			name: "v4 resolved",
+			payload: `{
+				"version": "4",
+				"groupKey": "{}/{alertname=\"HighLatency\"}",
+				"truncatedAlerts": 0,
+				"status": "resolved",
+				"receiver": "victorops",
+				"groupLabels": {"alertname": "HighLatency"},
+				"commonLabels": {"alertname": "HighLatency", "severity": "critical"},
+				"commonAnnotations": {"summary": "latency is high"},
+				"externalURL": "http://alertmanager.example.com",
+				"alerts": [
+					{
+						"status": "resolved",
+						"labels": {"alertname": "HighLatency", "severity": "critical"},
+						"annotations": {"summary": "latency is high"},
+						"startsAt": "2026-07-29T10:00:00Z",
+						"endsAt": "2026-07-29T10:05:00Z",
+						"generatorURL": "http://prometheus.example.com/graph"
+					}
+				]
+			}`,
+			wantGroupKey: `{}/{alertname="HighLatency"}`,
+			wantStatus:   "resolved",
+			wantAlerts:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Message
+			if err := json.Unmarshal([]byte(tt.payload), &m); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if m.GroupKey != tt.wantGroupKey {
+				t.Errorf("GroupKey = %q, want %q", m.GroupKey, tt.wantGroupKey)
+			}
+			if m.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", m.Status, tt.wantStatus)
+			}
+			if len(m.Alerts) != tt.wantAlerts {
+				t.Errorf("len(Alerts) = %d, want %d", len(m.Alerts), tt.wantAlerts)
+			}
+		})
+	}
+}